@@ -0,0 +1,116 @@
+package ulog
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// encodeCBOR renders a value as RFC 8949 CBOR. It implements only the
+// subset of the spec this package ever emits: nil, bool, signed/unsigned
+// integers, float64, string, []byte, []interface{} and map[string]interface{}.
+func encodeCBOR(value interface{}) []byte {
+	return appendCBOR(nil, value)
+}
+
+func appendCBOR(buffer []byte, value interface{}) []byte {
+	switch v := value.(type) {
+	case nil:
+		return append(buffer, 0xf6)
+	case bool:
+		if v {
+			return append(buffer, 0xf5)
+		}
+		return append(buffer, 0xf4)
+	case int:
+		return appendCBORInt(buffer, int64(v))
+	case int32:
+		return appendCBORInt(buffer, int64(v))
+	case int64:
+		return appendCBORInt(buffer, v)
+	case uint:
+		return appendCBORHead(buffer, 0, uint64(v))
+	case uint32:
+		return appendCBORHead(buffer, 0, uint64(v))
+	case uint64:
+		return appendCBORHead(buffer, 0, v)
+	case float32:
+		return appendCBORFloat(buffer, float64(v))
+	case float64:
+		return appendCBORFloat(buffer, v)
+	case string:
+		return appendCBORBytes(buffer, 3, []byte(v))
+	case []byte:
+		return appendCBORBytes(buffer, 2, v)
+	case []interface{}:
+		buffer = appendCBORHead(buffer, 4, uint64(len(v)))
+		for _, item := range v {
+			buffer = appendCBOR(buffer, item)
+		}
+		return buffer
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buffer = appendCBORHead(buffer, 5, uint64(len(v)))
+		for _, key := range keys {
+			buffer = appendCBOR(buffer, key)
+			buffer = appendCBOR(buffer, v[key])
+		}
+		return buffer
+	default:
+		return appendCBOR(buffer, fmt.Sprintf("%v", v))
+	}
+}
+
+func appendCBORInt(buffer []byte, v int64) []byte {
+	if v >= 0 {
+		return appendCBORHead(buffer, 0, uint64(v))
+	}
+	return appendCBORHead(buffer, 1, uint64(-(v + 1)))
+}
+
+func appendCBORBytes(buffer []byte, major byte, data []byte) []byte {
+	buffer = appendCBORHead(buffer, major, uint64(len(data)))
+	return append(buffer, data...)
+}
+
+func appendCBORFloat(buffer []byte, v float64) []byte {
+	buffer = append(buffer, 7<<5|27)
+	return appendUint64(buffer, math.Float64bits(v))
+}
+
+// appendCBORHead writes a major-type/length header, choosing the shortest
+// 1/2/4/8-byte length encoding per RFC 8949 section 3.
+func appendCBORHead(buffer []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buffer, major<<5|byte(n))
+	case n <= 0xff:
+		buffer = append(buffer, major<<5|24)
+		return append(buffer, byte(n))
+	case n <= 0xffff:
+		buffer = append(buffer, major<<5|25)
+		return appendUint16(buffer, uint16(n))
+	case n <= 0xffffffff:
+		buffer = append(buffer, major<<5|26)
+		return appendUint32(buffer, uint32(n))
+	default:
+		buffer = append(buffer, major<<5|27)
+		return appendUint64(buffer, n)
+	}
+}
+
+func appendUint16(buffer []byte, v uint16) []byte {
+	return append(buffer, byte(v>>8), byte(v))
+}
+
+func appendUint32(buffer []byte, v uint32) []byte {
+	return append(buffer, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+func appendUint64(buffer []byte, v uint64) []byte {
+	return append(buffer, byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
@@ -0,0 +1,127 @@
+package ulog
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// decodeCBORForTest decodes the subset of RFC 8949 encodeCBOR emits. It's a
+// minimal mirror of ulog/cborcat's decoder, kept test-local so this package
+// can round-trip its own encoder without importing the cborcat command.
+func decodeCBORForTest(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, fmt.Errorf("empty record")
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	length, offset, err := decodeCBORLengthForTest(data, 1, info)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch major {
+	case 0:
+		return length, offset, nil
+	case 1:
+		return -1 - int64(length), offset, nil
+	case 2:
+		end := offset + int(length)
+		return append([]byte{}, data[offset:end]...), end, nil
+	case 3:
+		end := offset + int(length)
+		return string(data[offset:end]), end, nil
+	case 4:
+		items := make([]interface{}, 0, length)
+		for i := uint64(0); i < length; i++ {
+			item, n, err := decodeCBORForTest(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			offset += n
+		}
+		return items, offset, nil
+	case 5:
+		result := make(map[string]interface{}, length)
+		for i := uint64(0); i < length; i++ {
+			key, n, err := decodeCBORForTest(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+			value, n, err := decodeCBORForTest(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+			result[fmt.Sprintf("%v", key)] = value
+		}
+		return result, offset, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, offset, nil
+		case 21:
+			return true, offset, nil
+		case 22:
+			return nil, offset, nil
+		case 27:
+			return math.Float64frombits(length), offset, nil
+		}
+		return nil, 0, fmt.Errorf("unsupported simple value %d", info)
+	}
+	return nil, 0, fmt.Errorf("unsupported major type %d", major)
+}
+
+func decodeCBORLengthForTest(data []byte, offset int, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), offset, nil
+	case info == 24:
+		return uint64(data[offset]), offset + 1, nil
+	case info == 25:
+		return uint64(binary.BigEndian.Uint16(data[offset:])), offset + 2, nil
+	case info == 26:
+		return uint64(binary.BigEndian.Uint32(data[offset:])), offset + 4, nil
+	case info == 27:
+		return binary.BigEndian.Uint64(data[offset:]), offset + 8, nil
+	}
+	return 0, 0, fmt.Errorf("unsupported length encoding %d", info)
+}
+
+func TestEncodeCBORRoundTrip(t *testing.T) {
+	cases := []struct {
+		name string
+		in   interface{}
+		want interface{}
+	}{
+		{"nil", nil, nil},
+		{"bool true", true, true},
+		{"bool false", false, false},
+		{"small int", 7, uint64(7)},
+		{"negative int", -42, int64(-42)},
+		{"large uint", uint64(1 << 40), uint64(1 << 40)},
+		{"float64", 3.5, 3.5},
+		{"string", "hello", "hello"},
+		{"bytes", []byte{1, 2, 3}, []byte{1, 2, 3}},
+		{"array", []interface{}{1, "two", 3.0}, []interface{}{uint64(1), "two", 3.0}},
+		{"map", map[string]interface{}{"a": 1, "b": "two"}, map[string]interface{}{"a": uint64(1), "b": "two"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoded := encodeCBOR(c.in)
+			got, n, err := decodeCBORForTest(encoded)
+			if err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if n != len(encoded) {
+				t.Errorf("consumed %d bytes, want %d", n, len(encoded))
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("round trip = %#v, want %#v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Command cborcat decodes the length-prefixed CBOR log records written by
+// ulog's file target when configured with format=cbor: [4-byte big-endian
+// length][CBOR body], repeated. Each record is printed to stdout as one
+// line of JSON.
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: cborcat <file>")
+		os.Exit(1)
+	}
+	file, err := os.Open(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer file.Close()
+
+	encoder := json.NewEncoder(os.Stdout)
+	var header [4]byte
+	for {
+		if _, err := io.ReadFull(file, header[:]); err != nil {
+			if err == io.EOF {
+				return
+			}
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		body := make([]byte, binary.BigEndian.Uint32(header[:]))
+		if _, err := io.ReadFull(file, body); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		value, _, err := decodeCBOR(body)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		if err := encoder.Encode(value); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// decodeCBOR decodes the subset of RFC 8949 that ulog's encoder emits: nil,
+// bool, signed/unsigned integers, float64, byte/text strings, arrays and
+// string-keyed maps. It returns the decoded value and the number of bytes
+// consumed from data.
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, fmt.Errorf("cborcat: empty record")
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	length, offset, err := decodeCBORLength(data, 1, info)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch major {
+	case 0:
+		return length, offset, nil
+	case 1:
+		return -1 - int64(length), offset, nil
+	case 2:
+		end := offset + int(length)
+		return append([]byte{}, data[offset:end]...), end, nil
+	case 3:
+		end := offset + int(length)
+		return string(data[offset:end]), end, nil
+	case 4:
+		items := make([]interface{}, 0, length)
+		for i := uint64(0); i < length; i++ {
+			item, n, err := decodeCBOR(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			items = append(items, item)
+			offset += n
+		}
+		return items, offset, nil
+	case 5:
+		result := make(map[string]interface{}, length)
+		for i := uint64(0); i < length; i++ {
+			key, n, err := decodeCBOR(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+			value, n, err := decodeCBOR(data[offset:])
+			if err != nil {
+				return nil, 0, err
+			}
+			offset += n
+			result[fmt.Sprintf("%v", key)] = value
+		}
+		return result, offset, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, offset, nil
+		case 21:
+			return true, offset, nil
+		case 22:
+			return nil, offset, nil
+		case 27:
+			return math.Float64frombits(length), offset, nil
+		}
+		return nil, 0, fmt.Errorf("cborcat: unsupported simple value %d", info)
+	}
+	return nil, 0, fmt.Errorf("cborcat: unsupported major type %d", major)
+}
+
+func decodeCBORLength(data []byte, offset int, info byte) (uint64, int, error) {
+	switch {
+	case info < 24:
+		return uint64(info), offset, nil
+	case info == 24:
+		return uint64(data[offset]), offset + 1, nil
+	case info == 25:
+		return uint64(binary.BigEndian.Uint16(data[offset:])), offset + 2, nil
+	case info == 26:
+		return uint64(binary.BigEndian.Uint32(data[offset:])), offset + 4, nil
+	case info == 27:
+		return binary.BigEndian.Uint64(data[offset:]), offset + 8, nil
+	}
+	return 0, 0, fmt.Errorf("cborcat: unsupported length encoding %d", info)
+}
@@ -0,0 +1,114 @@
+package ulog
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var consoleColorizerPattern = regexp.MustCompile(`"([^"]+)"\s*:`)
+
+// consoleSink is the built-in Sink behind the console(...) target: the
+// historical "timestamp + colored severity label" text prefix, with the
+// existing consoleColorizer regex used to tint JSON keys for map payloads.
+type consoleSink struct {
+	handle    io.Writer
+	timeMode  int
+	severity  bool
+	colors    bool
+	colorizer *regexp.Regexp
+	level     int32 // syslog.Priority, accessed atomically: see Level/setLevel
+	format    Formatter
+	sync.Mutex
+}
+
+func newConsoleSink(options string, defaultLevel syslog.Priority) *consoleSink {
+	this := &consoleSink{
+		handle:    os.Stderr,
+		timeMode:  TIME_DATE,
+		severity:  true,
+		colors:    true,
+		colorizer: consoleColorizerPattern,
+		level:     int32(defaultLevel),
+	}
+	for _, option := range optionPattern.FindAllStringSubmatch(options, -1) {
+		switch strings.ToLower(option[1]) {
+		case "output":
+			if strings.ToLower(option[2]) == "stdout" {
+				this.handle = os.Stdout
+			}
+		case "time":
+			value := strings.ToLower(option[2])
+			switch {
+			case value == "stamp" || value == "timestamp":
+				this.timeMode = TIME_TIMESTAMP
+			case !isTruthy(value):
+				this.timeMode = TIME_NONE
+			}
+		case "severity":
+			this.severity = isTruthy(option[2])
+		case "colors":
+			this.colors = isTruthy(option[2])
+		case "format":
+			this.format = formatterByName(option[2])
+		case "level":
+			if level, ok := severities[strings.ToLower(option[2])]; ok {
+				this.level = int32(level)
+			}
+		}
+	}
+	return this
+}
+
+func (this *consoleSink) Level() syslog.Priority {
+	return syslog.Priority(atomic.LoadInt32(&this.level))
+}
+func (this *consoleSink) Format() Formatter { return this.format }
+func (this *consoleSink) setLevel(level syslog.Priority) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+func (this *consoleSink) Close() {}
+
+func (this *consoleSink) Write(rec Record) error {
+	prefix := ""
+	switch this.timeMode {
+	case TIME_DATE:
+		prefix = fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d ", rec.Time.Year(), rec.Time.Month(), rec.Time.Day(), rec.Time.Hour(), rec.Time.Minute(), rec.Time.Second())
+	case TIME_TIMESTAMP:
+		prefix = fmt.Sprintf("%d ", rec.Time.Unix())
+	}
+	if this.severity {
+		if this.colors {
+			prefix += fmt.Sprintf("%s%s\x1b[0m", severityColors[rec.Severity], severityLabels[rec.Severity])
+		} else {
+			prefix += severityLabels[rec.Severity]
+		}
+	}
+	// An explicit format=... option picks a Formatter that renders its own
+	// caller=... / "caller" field; only add it here for the default
+	// rendering, or it shows up twice.
+	if this.format == nil && rec.Caller != "" {
+		prefix += rec.Caller + " "
+	}
+	var body string
+	switch {
+	case this.format != nil:
+		body = string(this.format.Format(rec))
+	case rec.Fields != nil:
+		body = string(jsonFormatter{}.Format(rec))
+		if this.colors {
+			body = this.colorizer.ReplaceAllString(body, "\"\x1b[37m$1\x1b[0m\":")
+		}
+	default:
+		body = rec.Message
+	}
+	this.Lock()
+	defer this.Unlock()
+	_, err := fmt.Fprintf(this.handle, "%s%s\n", prefix, body)
+	return err
+}
@@ -0,0 +1,46 @@
+package ulog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestConsoleSinkExplicitFormatDoesNotDuplicateCaller guards against a
+// regression where an explicit format=... option rendered rec.Caller both
+// in the sink's own prefix and again inside the chosen Formatter's output.
+func TestConsoleSinkExplicitFormatDoesNotDuplicateCaller(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := &consoleSink{
+		handle:   &buffer,
+		timeMode: TIME_NONE,
+		severity: false,
+		format:   textFormatter{},
+	}
+	if err := sink.Write(Record{Caller: "foo.go:10", Message: "hello"}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := buffer.String()
+	if n := strings.Count(got, "foo.go:10"); n != 1 {
+		t.Errorf("caller rendered %d times, want 1: %q", n, got)
+	}
+}
+
+// TestConsoleSinkDefaultFormatStillRendersCaller checks the default (no
+// explicit format=... option) map/JSON path still gets the caller both in
+// the prefix and as a "caller" key.
+func TestConsoleSinkDefaultFormatStillRendersCaller(t *testing.T) {
+	var buffer bytes.Buffer
+	sink := &consoleSink{
+		handle:   &buffer,
+		timeMode: TIME_NONE,
+		severity: false,
+	}
+	if err := sink.Write(Record{Caller: "foo.go:10", Fields: map[string]interface{}{"x": 1}}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	got := buffer.String()
+	if n := strings.Count(got, "foo.go:10"); n != 2 {
+		t.Errorf("caller rendered %d times, want 2 (prefix + JSON field): %q", n, got)
+	}
+}
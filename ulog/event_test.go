@@ -0,0 +1,149 @@
+package ulog
+
+import (
+	"log/syslog"
+	"sync"
+	"testing"
+)
+
+// recordingSink captures every Record it's given, for asserting on the
+// structured fields/message the Event/Context API produced.
+type recordingSink struct {
+	mu      sync.Mutex
+	records []Record
+}
+
+func (this *recordingSink) Write(rec Record) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.records = append(this.records, rec)
+	return nil
+}
+func (this *recordingSink) Close()                 {}
+func (this *recordingSink) Level() syslog.Priority { return syslog.LOG_DEBUG }
+func (this *recordingSink) Format() Formatter      { return nil }
+
+func (this *recordingSink) last() Record {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.records[len(this.records)-1]
+}
+
+// TestEventChainingSetsFieldsAndMessage covers the chainable Str/Int/Msg API:
+// log.Info().Str("user", u).Int("code", 500).Msg("failed").
+func TestEventChainingSetsFieldsAndMessage(t *testing.T) {
+	sink := &recordingSink{}
+	log := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+
+	log.Info().Str("user", "alice").Int("code", 500).Msg("failed")
+
+	rec := sink.last()
+	if rec.Fields["user"] != "alice" || rec.Fields["code"] != 500 {
+		t.Errorf("fields = %+v, want user=alice code=500", rec.Fields)
+	}
+	if rec.Fields["message"] != "failed" {
+		t.Errorf("fields[message] = %v, want \"failed\"", rec.Fields["message"])
+	}
+	if rec.Severity != syslog.LOG_INFO {
+		t.Errorf("severity = %v, want LOG_INFO", rec.Severity)
+	}
+}
+
+// TestEventMsgEmptyOmitsMessageField checks that Msg("") doesn't stamp a
+// blank "message" key onto an otherwise field-only event.
+func TestEventMsgEmptyOmitsMessageField(t *testing.T) {
+	sink := &recordingSink{}
+	log := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+
+	log.Info().Str("user", "alice").Msg("")
+
+	rec := sink.last()
+	if _, ok := rec.Fields["message"]; ok {
+		t.Errorf("fields = %+v, want no message key", rec.Fields)
+	}
+}
+
+// TestNilEventMethodsAreNoOps covers the old-style printf call path: entry()
+// returns nil once it's already dispatched via log(), and chaining off that
+// nil *Event must not panic.
+func TestNilEventMethodsAreNoOps(t *testing.T) {
+	sink := &recordingSink{}
+	log := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+
+	event := log.Info("already sent: %d", 42)
+	if event != nil {
+		t.Fatalf("entry() with args should return nil, got %+v", event)
+	}
+	event.Str("user", "alice").Int("code", 500).Msg("ignored")
+
+	if len(sink.records) != 1 {
+		t.Fatalf("records = %d, want 1 (only the printf call)", len(sink.records))
+	}
+	if sink.records[0].Message != "already sent: 42" {
+		t.Errorf("message = %q, want %q", sink.records[0].Message, "already sent: 42")
+	}
+}
+
+// TestWithLoggerMergesContextIntoStructuredEvent checks that fields set via
+// With() land in the map alongside an Event's own fields.
+func TestWithLoggerMergesContextIntoStructuredEvent(t *testing.T) {
+	sink := &recordingSink{}
+	parent := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+	child := parent.With().Str("service", "api").Logger()
+
+	child.Info().Int("code", 200).Msg("ok")
+
+	rec := sink.last()
+	if rec.Fields["service"] != "api" || rec.Fields["code"] != 200 {
+		t.Errorf("fields = %+v, want service=api code=200", rec.Fields)
+	}
+}
+
+// TestWithLoggerMergesContextIntoPrintfStyleCall checks mergeContext's
+// string-layout branch: context fields are rendered as a "key=value "
+// prefix ahead of the printf-formatted message, not dropped.
+func TestWithLoggerMergesContextIntoPrintfStyleCall(t *testing.T) {
+	sink := &recordingSink{}
+	parent := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+	child := parent.With().Str("service", "api").Logger()
+
+	child.Error("boom: %s", "disk full")
+
+	rec := sink.last()
+	want := "service=api boom: disk full"
+	if rec.Message != want {
+		t.Errorf("message = %q, want %q", rec.Message, want)
+	}
+}
+
+// TestNestedWithLoggersAccumulateContext covers With() chained off an
+// existing child logger: both layers' fields should reach the final record.
+func TestNestedWithLoggersAccumulateContext(t *testing.T) {
+	sink := &recordingSink{}
+	root := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+	service := root.With().Str("service", "api").Logger()
+	request := service.With().Str("request_id", "r-1").Logger()
+
+	request.Info().Msg("handled")
+
+	rec := sink.last()
+	if rec.Fields["service"] != "api" || rec.Fields["request_id"] != "r-1" {
+		t.Errorf("fields = %+v, want service=api request_id=r-1", rec.Fields)
+	}
+}
+
+// TestWithLoggerFieldOverridesOnConflict checks mergeContext's map branch:
+// an Event field with the same key as a With() context field wins, since
+// it's merged in after the inherited context.
+func TestWithLoggerFieldOverridesOnConflict(t *testing.T) {
+	sink := &recordingSink{}
+	parent := &ULog{level: syslog.LOG_DEBUG, sinks: []Sink{sink}}
+	child := parent.With().Str("code", "unset").Logger()
+
+	child.Info().Int("code", 404).Msg("not found")
+
+	rec := sink.last()
+	if rec.Fields["code"] != 404 {
+		t.Errorf("fields[code] = %v, want 404 (event field should win)", rec.Fields["code"])
+	}
+}
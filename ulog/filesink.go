@@ -0,0 +1,299 @@
+package ulog
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// fileSink is the built-in Sink behind the file(...) target: strftime path
+// rollover, size/age-based rotation with optional gzip compression, and the
+// historical "timestamp + severity label" text prefix.
+type fileSink struct {
+	path         string
+	previousPath string
+	handle       *os.File
+	timeMode     int
+	severity     bool
+	size         int64
+	maxSize      int64
+	maxDays      int
+	maxHours     int
+	compress     bool
+	perm         os.FileMode
+	lastCheck    time.Time
+	level        int32 // syslog.Priority, accessed atomically: see Level/setLevel
+	format       Formatter
+	sync.Mutex
+}
+
+func newFileSink(options string, defaultLevel syslog.Priority) *fileSink {
+	this := &fileSink{
+		timeMode:  TIME_DATE,
+		severity:  true,
+		perm:      0644,
+		lastCheck: time.Unix(0, 0),
+		level:     int32(defaultLevel),
+	}
+	for _, option := range optionPattern.FindAllStringSubmatch(options, -1) {
+		switch strings.ToLower(option[1]) {
+		case "path":
+			this.path = option[2]
+		case "time":
+			value := strings.ToLower(option[2])
+			switch {
+			case value == "stamp" || value == "timestamp":
+				this.timeMode = TIME_TIMESTAMP
+			case !isTruthy(value):
+				this.timeMode = TIME_NONE
+			}
+		case "severity":
+			this.severity = isTruthy(option[2])
+		case "maxsize":
+			this.maxSize = parseSize(option[2])
+		case "maxdays":
+			this.maxDays, _ = strconv.Atoi(option[2])
+		case "maxhours":
+			this.maxHours, _ = strconv.Atoi(option[2])
+		case "compress":
+			value := strings.ToLower(option[2])
+			this.compress = value == "gzip" || isTruthy(value)
+		case "perm":
+			if perm, err := strconv.ParseUint(option[2], 8, 32); err == nil {
+				this.perm = os.FileMode(perm)
+			}
+		case "format":
+			this.format = formatterByName(option[2])
+		case "level":
+			if level, ok := severities[strings.ToLower(option[2])]; ok {
+				this.level = int32(level)
+			}
+		}
+	}
+	if this.path == "" {
+		return nil
+	}
+	return this
+}
+
+func (this *fileSink) Level() syslog.Priority { return syslog.Priority(atomic.LoadInt32(&this.level)) }
+func (this *fileSink) Format() Formatter      { return this.format }
+func (this *fileSink) setLevel(level syslog.Priority) {
+	atomic.StoreInt32(&this.level, int32(level))
+}
+
+func (this *fileSink) Write(rec Record) error {
+	this.Lock()
+	defer this.Unlock()
+	if rec.Time.Sub(this.lastCheck) >= time.Second || this.handle == nil {
+		this.lastCheck = rec.Time
+		path := strftime(this.path, rec.Time)
+		if path != this.previousPath {
+			if this.handle != nil {
+				this.handle.Close()
+				this.handle = nil
+			}
+			this.previousPath = path
+		}
+		if this.handle == nil {
+			os.MkdirAll(filepath.Dir(path), 0755)
+			handle, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, this.perm)
+			if err != nil {
+				return err
+			}
+			this.handle = handle
+			this.size = 0
+			if info, err := handle.Stat(); err == nil {
+				this.size = info.Size()
+			}
+		}
+		if this.maxDays > 0 || this.maxHours > 0 {
+			go this.cleanup(filepath.Dir(path), filepath.Base(path))
+		}
+	}
+	if this.handle == nil {
+		return nil
+	}
+	if _, ok := this.format.(cborFormatter); ok {
+		return this.writeCBOR(rec)
+	}
+	line := this.prefix(rec) + this.body(rec) + "\n"
+	if _, err := this.handle.WriteString(line); err != nil {
+		return err
+	}
+	this.finishWrite(len(line), rec.Time)
+	return nil
+}
+
+func (this *fileSink) prefix(rec Record) string {
+	prefix := ""
+	switch this.timeMode {
+	case TIME_DATE:
+		prefix = fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d ", rec.Time.Year(), rec.Time.Month(), rec.Time.Day(), rec.Time.Hour(), rec.Time.Minute(), rec.Time.Second())
+	case TIME_TIMESTAMP:
+		prefix = fmt.Sprintf("%d ", rec.Time.Unix())
+	}
+	if this.severity {
+		prefix += severityLabels[rec.Severity]
+	}
+	// An explicit format=... option picks a Formatter that renders its own
+	// caller=... / "caller" field; only add it here for the default
+	// rendering, or it shows up twice.
+	if this.format == nil && rec.Caller != "" {
+		prefix += rec.Caller + " "
+	}
+	return prefix
+}
+
+func (this *fileSink) body(rec Record) string {
+	if this.format != nil {
+		return string(this.format.Format(rec))
+	}
+	if rec.Fields != nil {
+		return string(jsonFormatter{}.Format(rec))
+	}
+	return rec.Message
+}
+
+// writeCBOR writes a record as [4-byte big-endian length][CBOR body], with
+// no text prefix or trailing newline, so a reader (see ulog/cborcat) can
+// frame records without scanning for delimiters.
+func (this *fileSink) writeCBOR(rec Record) error {
+	body := this.format.Format(rec)
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], uint32(len(body)))
+	if _, err := this.handle.Write(header[:]); err != nil {
+		return err
+	}
+	if _, err := this.handle.Write(body); err != nil {
+		return err
+	}
+	this.finishWrite(len(header)+len(body), rec.Time)
+	return nil
+}
+
+// finishWrite accounts bytes written toward maxSize and rotates if the
+// active file just crossed the threshold. Callers must hold this.Mutex.
+func (this *fileSink) finishWrite(n int, now time.Time) {
+	this.size += int64(n)
+	if this.maxSize > 0 && this.size >= this.maxSize {
+		this.rotate(now)
+	}
+}
+
+// rotate closes the active file, renames it with a timestamp suffix and
+// clears handle so the next write reopens previousPath. Callers must hold
+// this.Mutex.
+func (this *fileSink) rotate(now time.Time) {
+	if this.handle == nil {
+		return
+	}
+	this.handle.Close()
+	this.handle = nil
+	this.size = 0
+	rotated := this.rotatedName(now)
+	if err := os.Rename(this.previousPath, rotated); err != nil {
+		return
+	}
+	if this.compress {
+		go compressRotated(rotated)
+	}
+}
+
+// rotatedName picks a not-yet-taken path for the rotated file. The base
+// suffix is nanosecond-resolution, but sustained writes against a small
+// maxsize can still rotate more than once within the same tick, so a
+// numeric counter is appended on collision rather than letting os.Rename
+// silently overwrite an earlier rotation. Callers must hold this.Mutex.
+func (this *fileSink) rotatedName(now time.Time) string {
+	base := fmt.Sprintf("%s.%s", this.previousPath, now.Format("20060102150405.000000000"))
+	candidate := base
+	for i := 1; ; i++ {
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s.%d", base, i)
+	}
+}
+
+// compressRotated gzips a rotated file and removes the uncompressed copy. It
+// runs in its own goroutine so hot-path writes never block on it.
+func compressRotated(path string) {
+	src, err := os.Open(path)
+	if err != nil {
+		return
+	}
+	defer src.Close()
+	dst, err := os.Create(path + ".gz")
+	if err != nil {
+		return
+	}
+	defer dst.Close()
+	writer := gzip.NewWriter(dst)
+	if _, err := io.Copy(writer, src); err != nil {
+		writer.Close()
+		return
+	}
+	if err := writer.Close(); err != nil {
+		return
+	}
+	os.Remove(path)
+}
+
+// cleanup deletes rotated files under dir whose name starts with base+"."
+// and whose age exceeds maxDays and/or maxHours. Both are upper bounds on
+// retention when set together, so a file is removed once it's past
+// whichever cutoff is reached first (e.g. maxdays=15,maxhours=48 deletes
+// after 48 hours, not 15 days). It runs in its own goroutine off the
+// per-second lastCheck tick so it never holds the sink's lock.
+func (this *fileSink) cleanup(dir, base string) {
+	if this.maxDays <= 0 && this.maxHours <= 0 {
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	var hoursCutoff, daysCutoff time.Time
+	if this.maxHours > 0 {
+		hoursCutoff = now.Add(-time.Duration(this.maxHours) * time.Hour)
+	}
+	if this.maxDays > 0 {
+		daysCutoff = now.Add(-time.Duration(this.maxDays) * 24 * time.Hour)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, base+".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		expired := (!hoursCutoff.IsZero() && info.ModTime().Before(hoursCutoff)) ||
+			(!daysCutoff.IsZero() && info.ModTime().Before(daysCutoff))
+		if !expired {
+			continue
+		}
+		os.Remove(filepath.Join(dir, name))
+	}
+}
+
+func (this *fileSink) Close() {
+	this.Lock()
+	defer this.Unlock()
+	if this.handle != nil {
+		this.handle.Close()
+		this.handle = nil
+	}
+}
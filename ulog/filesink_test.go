@@ -0,0 +1,112 @@
+package ulog
+
+import (
+	"log/syslog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestFileSinkCleanupHonorsBothCutoffs covers the maxdays/maxhours
+// interaction fixed alongside this feature: when both are set, a rotated
+// file is removed once it's past whichever cutoff is reached first, not
+// only once both agree.
+func TestFileSinkCleanupHonorsBothCutoffs(t *testing.T) {
+	dir := t.TempDir()
+	base := "app.log"
+
+	write := func(name string, age time.Duration) {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		stamp := time.Now().Add(-age)
+		if err := os.Chtimes(path, stamp, stamp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(base+".old-hours", 72*time.Hour)  // past maxHours, within maxDays
+	write(base+".old-days", 20*24*time.Hour) // past both
+	write(base+".fresh", time.Hour)          // within both
+
+	sink := &fileSink{maxDays: 15, maxHours: 48}
+	sink.cleanup(dir, base)
+
+	assertGone := func(name string) {
+		if _, err := os.Stat(filepath.Join(dir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got err=%v", name, err)
+		}
+	}
+	assertExists := func(name string) {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to survive cleanup, got err=%v", name, err)
+		}
+	}
+	assertGone(base + ".old-hours")
+	assertGone(base + ".old-days")
+	assertExists(base + ".fresh")
+}
+
+// TestFileSinkRotateDoesNotClobber reproduces the exact workload that used
+// to destroy earlier rotations: several writes crossing maxsize within the
+// same (second-granularity) tick must each land in their own rotated file
+// instead of being silently overwritten by os.Rename.
+func TestFileSinkRotateDoesNotClobber(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	sink := newFileSink("path="+path+",maxsize=1", syslog.LOG_INFO)
+	if sink == nil {
+		t.Fatal("newFileSink returned nil")
+	}
+	defer sink.Close()
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		rec := Record{Time: now, Severity: syslog.LOG_INFO, Message: "line"}
+		if err := sink.Write(rec); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotated := 0
+	for _, entry := range entries {
+		if entry.Name() != "app.log" {
+			rotated++
+		}
+	}
+	if rotated != 5 {
+		t.Errorf("expected 5 distinct rotated files, got %d", rotated)
+	}
+}
+
+// TestFileSinkPrefixExplicitFormatDoesNotDuplicateCaller guards against a
+// regression where an explicit format=... option rendered rec.Caller both
+// in the sink's own prefix and again inside the chosen Formatter's output.
+func TestFileSinkPrefixExplicitFormatDoesNotDuplicateCaller(t *testing.T) {
+	sink := &fileSink{timeMode: TIME_NONE, format: textFormatter{}}
+	rec := Record{Caller: "foo.go:10", Message: "hello"}
+	line := sink.prefix(rec) + sink.body(rec)
+	if got := strings.Count(line, "foo.go:10"); got != 1 {
+		t.Errorf("caller rendered %d times, want 1: %q", got, line)
+	}
+}
+
+// TestFileSinkPrefixDefaultFormatStillRendersCaller checks the default (no
+// explicit format=... option) map/JSON path still gets the caller both in
+// the prefix and as a "caller" key.
+func TestFileSinkPrefixDefaultFormatStillRendersCaller(t *testing.T) {
+	sink := &fileSink{timeMode: TIME_NONE}
+	rec := Record{Caller: "foo.go:10", Fields: map[string]interface{}{"x": 1}}
+	line := sink.prefix(rec) + sink.body(rec)
+	if got := strings.Count(line, "foo.go:10"); got != 2 {
+		t.Errorf("caller rendered %d times, want 2 (prefix + JSON field): %q", got, line)
+	}
+}
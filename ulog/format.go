@@ -0,0 +1,130 @@
+package ulog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Formatter renders a Record to bytes. Sinks fall back to this package's
+// historical rendering (plain text, or JSON for map/structured payloads)
+// when no Formatter is configured; an explicit format=json|logfmt option
+// overrides that default.
+type Formatter interface {
+	Format(rec Record) []byte
+}
+
+type textFormatter struct{}
+
+func (textFormatter) Format(rec Record) []byte {
+	if len(rec.Fields) == 0 {
+		switch {
+		case rec.Caller == "":
+			return []byte(rec.Message)
+		case rec.Message == "":
+			return []byte(fmt.Sprintf("caller=%s", rec.Caller))
+		default:
+			return []byte(fmt.Sprintf("caller=%s %s", rec.Caller, rec.Message))
+		}
+	}
+	keys := make([]string, 0, len(rec.Fields))
+	for key := range rec.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var buffer bytes.Buffer
+	if rec.Caller != "" {
+		fmt.Fprintf(&buffer, "caller=%s ", rec.Caller)
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&buffer, "%s=%v ", key, rec.Fields[key])
+	}
+	return bytes.TrimSpace(buffer.Bytes())
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(rec Record) []byte {
+	payload := make(map[string]interface{}, len(rec.Fields)+2)
+	for key, value := range rec.Fields {
+		payload[key] = value
+	}
+	if rec.Message != "" {
+		payload["message"] = rec.Message
+	}
+	if rec.Caller != "" {
+		payload["caller"] = rec.Caller
+	}
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(payload); err != nil {
+		return []byte(rec.Message)
+	}
+	return bytes.TrimSpace(buffer.Bytes())
+}
+
+type logfmtFormatter struct{}
+
+func (logfmtFormatter) Format(rec Record) []byte {
+	var buffer bytes.Buffer
+	if rec.Caller != "" {
+		fmt.Fprintf(&buffer, "caller=%s ", rec.Caller)
+	}
+	if rec.Message != "" {
+		fmt.Fprintf(&buffer, "msg=%q ", rec.Message)
+	}
+	keys := make([]string, 0, len(rec.Fields))
+	for key := range rec.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if str, ok := rec.Fields[key].(string); ok && strings.ContainsAny(str, " \"=") {
+			fmt.Fprintf(&buffer, "%s=%q ", key, str)
+		} else {
+			fmt.Fprintf(&buffer, "%s=%v ", key, rec.Fields[key])
+		}
+	}
+	return bytes.TrimSpace(buffer.Bytes())
+}
+
+// cborFormatter renders a Record as a CBOR map (severity, time, message
+// and/or fields), mirroring jsonFormatter's payload shape but binary-encoded
+// via encodeCBOR. Used by the file and syslog targets' format=cbor option.
+type cborFormatter struct{}
+
+func (cborFormatter) Format(rec Record) []byte {
+	payload := make(map[string]interface{}, len(rec.Fields)+3)
+	for key, value := range rec.Fields {
+		payload[key] = value
+	}
+	if rec.Message != "" {
+		payload["message"] = rec.Message
+	}
+	if rec.Caller != "" {
+		payload["caller"] = rec.Caller
+	}
+	payload["severity"] = strings.TrimSpace(severityLabels[rec.Severity])
+	payload["time"] = rec.Time.Unix()
+	return encodeCBOR(payload)
+}
+
+// formatterByName resolves a target string's format=... option. Unknown or
+// empty names keep the sink's own default rendering (nil Formatter).
+func formatterByName(name string) Formatter {
+	switch strings.ToLower(name) {
+	case "text":
+		return textFormatter{}
+	case "json":
+		return jsonFormatter{}
+	case "logfmt":
+		return logfmtFormatter{}
+	case "cbor":
+		return cborFormatter{}
+	default:
+		return nil
+	}
+}
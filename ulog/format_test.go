@@ -0,0 +1,14 @@
+package ulog
+
+import "testing"
+
+// TestTextFormatterPreservesMessageWithCaller guards against a regression
+// where caller enrichment on a plain printf-style record (no Fields) made
+// the early-return guard skip, dropping rec.Message entirely.
+func TestTextFormatterPreservesMessageWithCaller(t *testing.T) {
+	got := string(textFormatter{}.Format(Record{Message: "hello world", Caller: "foo.go:10"}))
+	want := "caller=foo.go:10 hello world"
+	if got != want {
+		t.Errorf("Format() = %q, want %q", got, want)
+	}
+}
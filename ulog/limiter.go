@@ -0,0 +1,87 @@
+package ulog
+
+import (
+	"log/syslog"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// levelLimiter gates one severity's log calls per option(sample=...,
+// rate=...,burst=...): deterministic 1-in-N sampling, a rate/burst token
+// bucket, or both (a call must clear whichever of the two is configured).
+// Every counter is accessed via atomic so the common allow() path never
+// takes a lock.
+type levelLimiter struct {
+	sampleN    uint64
+	counter    uint64
+	rate       float64
+	burst      int64
+	tokens     int64
+	lastNanos  int64
+	suppressed uint64
+}
+
+// allow reports whether this call should proceed, counting it toward the
+// suppressed total otherwise.
+func (this *levelLimiter) allow() bool {
+	allowed := true
+	if this.sampleN > 1 {
+		n := atomic.AddUint64(&this.counter, 1)
+		allowed = n%this.sampleN == 0
+	}
+	if allowed && this.rate > 0 && !this.takeToken() {
+		allowed = false
+	}
+	if !allowed {
+		atomic.AddUint64(&this.suppressed, 1)
+	}
+	return allowed
+}
+
+// takeToken refills the bucket for the whole tokens earned since the last
+// refill, then tries to consume one token. The refill timestamp only
+// advances when at least one token is added, so calls arriving faster than
+// rate still accumulate elapsed time toward the next token. Refill and
+// consumption are two separate atomics rather than one combined CAS, so
+// under heavy contention a handful of calls may see a slightly stale token
+// count - an acceptable trade for a lock-free hot path in a rate limiter.
+func (this *levelLimiter) takeToken() bool {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&this.lastNanos)
+	if last == 0 {
+		atomic.CompareAndSwapInt64(&this.lastNanos, 0, now)
+	} else if elapsed := now - last; elapsed > 0 {
+		refill := int64(float64(elapsed) / float64(time.Second) * this.rate)
+		if refill > 0 && atomic.CompareAndSwapInt64(&this.lastNanos, last, now) {
+			if updated := atomic.AddInt64(&this.tokens, refill); updated > this.burst {
+				atomic.StoreInt64(&this.tokens, this.burst)
+			}
+		}
+	}
+	for {
+		tokens := atomic.LoadInt64(&this.tokens)
+		if tokens <= 0 {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&this.tokens, tokens, tokens-1) {
+			return true
+		}
+	}
+}
+
+// scopedLevel splits a dotted option key such as "sample.debug" into its
+// severity. scoped reports whether key carried a dot at all (a bare key
+// has none and applies to every level's default); when scoped is true, ok
+// reports whether the suffix after the dot was a recognized severity name.
+// A dotted key with an unrecognized suffix (e.g. "sample.warn" - this
+// package spells it "warning") must never be mistaken for a bare key,
+// or it would silently overwrite the default for every level.
+func scopedLevel(key, base string) (level syslog.Priority, scoped bool, ok bool) {
+	suffix := strings.TrimPrefix(key, base+".")
+	if suffix == key {
+		return 0, false, false
+	}
+	level, known := severities[suffix]
+	return level, true, known
+}
@@ -0,0 +1,89 @@
+package ulog
+
+import (
+	"log/syslog"
+	"testing"
+)
+
+// TestScopedLevelDistinguishesBareFromUnrecognizedSuffix checks that a
+// dotted key with an unrecognized severity (e.g. the common "warn" vs this
+// package's "warning") is reported distinctly from a bare, unscoped key -
+// conflating the two used to fall back to overwriting every level's
+// default on a typo.
+func TestScopedLevelDistinguishesBareFromUnrecognizedSuffix(t *testing.T) {
+	if _, scoped, _ := scopedLevel("sample", "sample"); scoped {
+		t.Error("bare key should not be scoped")
+	}
+	level, scoped, ok := scopedLevel("sample.warning", "sample")
+	if !scoped || !ok || level != syslog.LOG_WARNING {
+		t.Errorf("sample.warning: scoped=%v ok=%v level=%v, want true/true/%v", scoped, ok, level, syslog.LOG_WARNING)
+	}
+	if _, scoped, ok := scopedLevel("sample.warn", "sample"); !scoped || ok {
+		t.Errorf("sample.warn: scoped=%v ok=%v, want true/false (unrecognized severity, not a bare key)", scoped, ok)
+	}
+}
+
+// TestLoadIgnoresTypoedScopeInsteadOfOverwritingDefault reproduces the
+// reported bug: option(sample.warn=5) used to fall through to
+// defaultSample, throttling every severity (including error) instead of
+// being ignored as an unrecognized scope.
+func TestLoadIgnoresTypoedScopeInsteadOfOverwritingDefault(t *testing.T) {
+	log := New("console() option(sample.warn=5)")
+	if limiter := log.limiters[syslog.LOG_ERR]; limiter != nil {
+		t.Errorf("error level got a limiter from a typoed sample.warn scope: %+v", limiter)
+	}
+}
+
+// TestLevelLimiterSampling checks the deterministic 1-in-N sampling path:
+// exactly every Nth call is allowed, and the rest count toward suppressed.
+func TestLevelLimiterSampling(t *testing.T) {
+	limiter := &levelLimiter{sampleN: 3}
+	var allowed int
+	for i := 0; i < 9; i++ {
+		if limiter.allow() {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("allowed = %d, want 3", allowed)
+	}
+	if limiter.suppressed != 6 {
+		t.Errorf("suppressed = %d, want 6", limiter.suppressed)
+	}
+}
+
+// TestLevelLimiterTokenBucketBurst checks that a fresh token-bucket limiter
+// allows exactly burst calls before it starts throttling, with no rate
+// elapsed between them.
+func TestLevelLimiterTokenBucketBurst(t *testing.T) {
+	limiter := &levelLimiter{rate: 100, burst: 4, tokens: 4}
+	var allowed int
+	for i := 0; i < 10; i++ {
+		if limiter.allow() {
+			allowed++
+		}
+	}
+	if allowed != 4 {
+		t.Errorf("allowed = %d, want 4 (the burst capacity)", allowed)
+	}
+	if limiter.suppressed != 6 {
+		t.Errorf("suppressed = %d, want 6", limiter.suppressed)
+	}
+}
+
+// TestLevelLimiterSampleAndRateBothGate checks that when both sample and
+// rate are configured, a call must clear both to be allowed: sampling alone
+// would allow every 2nd call, but burst=1 with no elapsed time still caps
+// the total allowed at 1.
+func TestLevelLimiterSampleAndRateBothGate(t *testing.T) {
+	limiter := &levelLimiter{sampleN: 2, rate: 100, burst: 1, tokens: 1}
+	var allowed int
+	for i := 0; i < 6; i++ {
+		if limiter.allow() {
+			allowed++
+		}
+	}
+	if allowed != 1 {
+		t.Errorf("allowed = %d, want 1", allowed)
+	}
+}
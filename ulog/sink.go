@@ -0,0 +1,157 @@
+package ulog
+
+import (
+	"log/syslog"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink receives every Record a ULog accepts past its level gate whose
+// severity is within the sink's own Level(). The
+// built-in file/console/syslog targets parsed by Load are sinks; callers can
+// register their own (Kafka, HTTP, Loki, journald, an in-memory ring buffer
+// for tests, ...) with ULog.AddSink.
+type Sink interface {
+	Write(rec Record) error
+	Close()
+	Level() syslog.Priority
+	Format() Formatter
+}
+
+// Record is the payload handed to a Sink for a single log call. Exactly one
+// of Message (printf-style calls) or Fields (map/structured calls) is set.
+// Caller is the rendered call-site (see option(caller=...)); empty when
+// caller enrichment is off.
+type Record struct {
+	Time     time.Time
+	Severity syslog.Priority
+	Message  string
+	Fields   map[string]interface{}
+	Caller   string
+}
+
+// MultiSink fans a Record out to every wrapped sink concurrently, skipping
+// sinks whose Level() is below the record's severity.
+type MultiSink struct {
+	sinks []Sink
+}
+
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+func (this *MultiSink) Write(rec Record) error {
+	var wg sync.WaitGroup
+	errs := make([]error, len(this.sinks))
+	for index, sink := range this.sinks {
+		if rec.Severity > sink.Level() {
+			continue
+		}
+		wg.Add(1)
+		go func(index int, sink Sink) {
+			defer wg.Done()
+			errs[index] = sink.Write(rec)
+		}(index, sink)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (this *MultiSink) Close() {
+	for _, sink := range this.sinks {
+		sink.Close()
+	}
+}
+
+func (this *MultiSink) Level() syslog.Priority {
+	level := syslog.Priority(-1)
+	for _, sink := range this.sinks {
+		if sink.Level() > level {
+			level = sink.Level()
+		}
+	}
+	return level
+}
+
+func (this *MultiSink) Format() Formatter { return nil }
+
+// AsyncSink wraps a Sink with a bounded channel so a slow downstream (a
+// remote collector, say) never blocks the logging hot path. Once the buffer
+// is full, the oldest queued record is dropped in favor of the new one.
+// closed guards against Write sending on queue after Close has started
+// closing it - ULog.log() calls Sink.Write outside its own lock, so an
+// AddSink-registered AsyncSink is exposed to a concurrent Close exactly
+// like the built-in sinks.
+type AsyncSink struct {
+	sink    Sink
+	queue   chan Record
+	done    chan struct{}
+	dropped uint64
+	closed  bool
+	mu      sync.RWMutex
+}
+
+func NewAsyncSink(sink Sink, buffer int) *AsyncSink {
+	this := &AsyncSink{sink: sink, queue: make(chan Record, buffer), done: make(chan struct{})}
+	go this.run()
+	return this
+}
+
+func (this *AsyncSink) run() {
+	for rec := range this.queue {
+		this.sink.Write(rec)
+	}
+	close(this.done)
+}
+
+func (this *AsyncSink) Write(rec Record) error {
+	this.mu.RLock()
+	defer this.mu.RUnlock()
+	if this.closed {
+		return nil
+	}
+	select {
+	case this.queue <- rec:
+		return nil
+	default:
+	}
+	select {
+	case <-this.queue:
+		atomic.AddUint64(&this.dropped, 1)
+	default:
+	}
+	select {
+	case this.queue <- rec:
+	default:
+		atomic.AddUint64(&this.dropped, 1)
+	}
+	return nil
+}
+
+// Dropped reports how many records were discarded so far to make room for
+// a new record in a full buffer.
+func (this *AsyncSink) Dropped() uint64 {
+	return atomic.LoadUint64(&this.dropped)
+}
+
+func (this *AsyncSink) Close() {
+	this.mu.Lock()
+	if this.closed {
+		this.mu.Unlock()
+		return
+	}
+	this.closed = true
+	close(this.queue)
+	this.mu.Unlock()
+	<-this.done
+	this.sink.Close()
+}
+
+func (this *AsyncSink) Level() syslog.Priority { return this.sink.Level() }
+func (this *AsyncSink) Format() Formatter      { return this.sink.Format() }
@@ -0,0 +1,104 @@
+package ulog
+
+import (
+	"log/syslog"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// countingSink is a minimal in-memory Sink for exercising MultiSink/AsyncSink
+// without a real file/console/syslog destination.
+type countingSink struct {
+	level  syslog.Priority
+	writes int64
+	closes int64
+}
+
+func (this *countingSink) Write(rec Record) error {
+	atomic.AddInt64(&this.writes, 1)
+	return nil
+}
+func (this *countingSink) Close()                 { atomic.AddInt64(&this.closes, 1) }
+func (this *countingSink) Level() syslog.Priority { return this.level }
+func (this *countingSink) Format() Formatter      { return nil }
+
+func TestMultiSinkFansOutAndRespectsLevel(t *testing.T) {
+	loud := &countingSink{level: syslog.LOG_DEBUG}
+	quiet := &countingSink{level: syslog.LOG_ERR}
+	multi := NewMultiSink(loud, quiet)
+
+	if err := multi.Write(Record{Severity: syslog.LOG_DEBUG}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if got := atomic.LoadInt64(&loud.writes); got != 1 {
+		t.Errorf("loud.writes = %d, want 1", got)
+	}
+	if got := atomic.LoadInt64(&quiet.writes); got != 0 {
+		t.Errorf("quiet.writes = %d, want 0 (below its level)", got)
+	}
+
+	multi.Close()
+	if atomic.LoadInt64(&loud.closes) != 1 || atomic.LoadInt64(&quiet.closes) != 1 {
+		t.Errorf("Close did not reach every wrapped sink")
+	}
+}
+
+// blockingSink lets a test pin the AsyncSink drain goroutine mid-Write so
+// the bounded queue can be driven to overflow deterministically.
+type blockingSink struct {
+	started sync.Once
+	startCh chan struct{}
+	release chan struct{}
+}
+
+func (this *blockingSink) Write(rec Record) error {
+	this.started.Do(func() { close(this.startCh) })
+	<-this.release
+	return nil
+}
+func (this *blockingSink) Close()                 {}
+func (this *blockingSink) Level() syslog.Priority { return syslog.LOG_DEBUG }
+func (this *blockingSink) Format() Formatter      { return nil }
+
+func TestAsyncSinkDropsOldestWhenFull(t *testing.T) {
+	inner := &blockingSink{startCh: make(chan struct{}), release: make(chan struct{})}
+	async := NewAsyncSink(inner, 1)
+
+	async.Write(Record{Severity: syslog.LOG_DEBUG})
+	<-inner.startCh // run() has pulled the first record and is blocked in Write
+
+	for i := 0; i < 5; i++ {
+		async.Write(Record{Severity: syslog.LOG_DEBUG})
+	}
+	if async.Dropped() == 0 {
+		t.Error("Dropped() = 0, want at least one record dropped under a buffer of 1")
+	}
+
+	close(inner.release)
+	async.Close()
+}
+
+// TestAsyncSinkWriteDoesNotPanicOnConcurrentClose reproduces the panic a
+// Write racing a Close used to hit: sending on this.queue after Close had
+// already closed it.
+func TestAsyncSinkWriteDoesNotPanicOnConcurrentClose(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		inner := &countingSink{level: syslog.LOG_DEBUG}
+		async := NewAsyncSink(inner, 4)
+
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				async.Write(Record{Severity: syslog.LOG_DEBUG})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			async.Close()
+		}()
+		wg.Wait()
+	}
+}
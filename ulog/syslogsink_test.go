@@ -0,0 +1,41 @@
+package ulog
+
+import (
+	"log/syslog"
+	"net"
+	"sync"
+	"testing"
+)
+
+// TestSyslogSinkWriteCloseRace reproduces the nil-pointer panic a Write
+// racing a Close used to hit: the lock used to be released after dialing
+// but before the actual write, so a concurrent Close could nil out
+// this.handle in between.
+func TestSyslogSinkWriteCloseRace(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("no local UDP available: %v", err)
+	}
+	defer conn.Close()
+
+	sink := newSyslogSink("remote="+conn.LocalAddr().String(), "test", syslog.LOG_DEBUG)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				sink.Write(Record{Severity: syslog.LOG_INFO, Message: "hi"})
+			}
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sink.Close()
+		}()
+	}
+	wg.Wait()
+}
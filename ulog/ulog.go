@@ -4,14 +4,17 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io"
 	"log/syslog"
 	"os"
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,6 +24,13 @@ const (
 	TIME_TIMESTAMP
 )
 
+const (
+	callerOff int = iota
+	callerShort
+	callerPkg
+	callerFull
+)
+
 var facilities = map[string]syslog.Priority{
 	"user":   syslog.LOG_USER,
 	"daemon": syslog.LOG_DAEMON,
@@ -54,168 +64,314 @@ var severityColors = map[syslog.Priority]string{
 	syslog.LOG_DEBUG:   "\x1b[32m",
 }
 
+var targetPattern = regexp.MustCompile(`(file|console|syslog|option)\s*\(([^\)]*)\)`)
+var optionPattern = regexp.MustCompile(`([^:=,\s]+)\s*[:=]\s*([^,\s]+)`)
+
+// isTruthy matches the "1|true|on|yes" spelling this package's target string
+// options have always accepted for boolean flags.
+func isTruthy(value string) bool {
+	switch strings.ToLower(value) {
+	case "1", "true", "on", "yes":
+		return true
+	default:
+		return false
+	}
+}
+
+// CallerMarshalFunc renders a captured call site for inclusion in a Record.
+// pc is provided so a custom func can recover the function name (via
+// runtime.FuncForPC) in addition to file/line.
+type CallerMarshalFunc func(pc uintptr, file string, line int) string
+
 type ULog struct {
-	file, console, syslog bool
-	fileHandle            *os.File
-	filePath              string
-	filePreviousPath      string
-	fileTime              int
-	fileSeverity          bool
-	consoleHandle         io.Writer
-	consoleTime           int
-	consoleSeverity       bool
-	consoleColors         bool
-	consoleColorizer      *regexp.Regexp
-	syslogHandle          *syslog.Writer
-	syslogRemote          string
-	syslogName            string
-	syslogFacility        syslog.Priority
-	optionUTC             bool
-	lastCheck             time.Time
-	level                 syslog.Priority
+	sinks         []Sink
+	optionUTC     bool
+	level         syslog.Priority
+	callerMode    int32
+	callerMarshal atomic.Value
+	callerCache   sync.Map
+	limiters      map[syslog.Priority]*levelLimiter
+	suppressCheck int64
+	parent        *ULog
+	context       map[string]interface{}
 	sync.Mutex
 }
 
 func New(target string) *ULog {
-	log := &ULog{
-		fileHandle:   nil,
-		syslogHandle: nil,
-	}
+	log := &ULog{}
 	return log.Load(target)
 }
 
+// Load (re)configures this logger from a target string such as
+// `file(path=app.log,maxsize=100M) console(colors=true) option(level=debug)`.
+// Each file/console/syslog clause becomes its own Sink; additional sinks can
+// be registered with AddSink. option(sample=N) and option(rate=R,burst=B)
+// throttle noisy call sites instead of dropping them outright; either can be
+// scoped to one severity with a dotted key, e.g. option(sample.debug=100).
 func (this *ULog) Load(target string) *ULog {
 	this.Close()
 	this.Lock()
 	defer this.Unlock()
 
-	this.file = false
-	this.filePath = ""
-	this.filePreviousPath = ""
-	this.fileTime = TIME_DATE
-	this.fileSeverity = true
-	this.console = false
-	this.consoleTime = TIME_DATE
-	this.consoleSeverity = true
-	this.consoleColors = true
-	this.consoleColorizer = regexp.MustCompile(`"([^"]+)"\s*:`)
-	this.consoleHandle = os.Stderr
-	this.syslog = false
-	this.syslogRemote = ""
-	this.syslogName = filepath.Base(os.Args[0])
-	this.syslogFacility = syslog.LOG_DAEMON
+	this.sinks = nil
 	this.optionUTC = false
-	this.lastCheck = time.Unix(0, 0)
-	this.level = syslog.LOG_INFO
-	for _, target := range regexp.MustCompile("(file|console|syslog|option)\\s*\\(([^\\)]*)\\)").FindAllStringSubmatch(target, -1) {
-		switch strings.ToLower(target[1]) {
-		case "file":
-			this.file = true
-			for _, option := range regexp.MustCompile("([^:=,\\s]+)\\s*[:=]\\s*([^,\\s]+)").FindAllStringSubmatch(target[2], -1) {
-				switch strings.ToLower(option[1]) {
-				case "path":
-					this.filePath = option[2]
-				case "time":
-					option[2] = strings.ToLower(option[2])
-					switch {
-					case option[2] == "stamp" || option[2] == "timestamp":
-						this.fileTime = TIME_TIMESTAMP
-					case option[2] != "1" && option[2] != "true" && option[2] != "on" && option[2] != "yes":
-						this.fileTime = TIME_NONE
-					}
-				case "severity":
-					option[2] = strings.ToLower(option[2])
-					if option[2] != "1" && option[2] != "true" && option[2] != "on" && option[2] != "yes" {
-						this.fileSeverity = false
-					}
+	atomic.StoreInt32(&this.callerMode, int32(callerOff))
+	this.callerCache.Range(func(key, _ interface{}) bool {
+		this.callerCache.Delete(key)
+		return true
+	})
+	this.limiters = nil
+	defaultLevel := syslog.LOG_INFO
+	name := filepath.Base(os.Args[0])
+
+	var defaultSample uint64
+	var defaultRate float64
+	var defaultBurst int64
+	sampleByLevel := map[syslog.Priority]uint64{}
+	rateByLevel := map[syslog.Priority]float64{}
+	burstByLevel := map[syslog.Priority]int64{}
+
+	clauses := targetPattern.FindAllStringSubmatch(target, -1)
+	for _, clause := range clauses {
+		if strings.ToLower(clause[1]) != "option" {
+			continue
+		}
+		for _, option := range optionPattern.FindAllStringSubmatch(clause[2], -1) {
+			key := strings.ToLower(option[1])
+			switch {
+			case key == "utc":
+				this.optionUTC = isTruthy(option[2])
+			case key == "level":
+				if level, ok := severities[strings.ToLower(option[2])]; ok {
+					defaultLevel = level
 				}
-			}
-			if this.filePath == "" {
-				this.file = false
-			}
-		case "console":
-			this.console = true
-			for _, option := range regexp.MustCompile("([^:=,\\s]+)\\s*[:=]\\s*([^,\\s]+)").FindAllStringSubmatch(target[2], -1) {
-				option[2] = strings.ToLower(option[2])
-				switch strings.ToLower(option[1]) {
-				case "output":
-					if option[2] == "stdout" {
-						this.consoleHandle = os.Stdout
-					}
-				case "time":
-					switch {
-					case option[2] == "stamp" || option[2] == "timestamp":
-						this.consoleTime = TIME_TIMESTAMP
-					case option[2] != "1" && option[2] != "true" && option[2] != "on" && option[2] != "yes":
-						this.consoleTime = TIME_NONE
-					}
-				case "severity":
-					if option[2] != "1" && option[2] != "true" && option[2] != "on" && option[2] != "yes" {
-						this.consoleSeverity = false
-					}
-				case "colors":
-					if option[2] != "1" && option[2] != "true" && option[2] != "on" && option[2] != "yes" {
-						this.consoleColors = false
+			case key == "caller":
+				switch strings.ToLower(option[2]) {
+				case "full":
+					atomic.StoreInt32(&this.callerMode, int32(callerFull))
+				case "pkg":
+					atomic.StoreInt32(&this.callerMode, int32(callerPkg))
+				case "short":
+					atomic.StoreInt32(&this.callerMode, int32(callerShort))
+				default:
+					if isTruthy(option[2]) {
+						atomic.StoreInt32(&this.callerMode, int32(callerShort))
 					}
 				}
-			}
-		case "syslog":
-			this.syslog = true
-			for _, option := range regexp.MustCompile("([^:=,\\s]+)\\s*[:=]\\s*([^,\\s]+)").FindAllStringSubmatch(target[2], -1) {
-				switch strings.ToLower(option[1]) {
-				case "remote":
-					this.syslogRemote = option[2]
-					if !regexp.MustCompile(":\\d+$").MatchString(this.syslogRemote) {
-						this.syslogRemote += ":514"
-					}
-				case "name":
-					this.syslogName = option[2]
-				case "facility":
-					this.syslogFacility = facilities[strings.ToLower(option[2])]
+			case key == "sample" || strings.HasPrefix(key, "sample."):
+				n, _ := strconv.ParseUint(option[2], 10, 64)
+				if level, scoped, ok := scopedLevel(key, "sample"); !scoped {
+					defaultSample = n
+				} else if ok {
+					sampleByLevel[level] = n
 				}
-			}
-		case "option":
-			for _, option := range regexp.MustCompile("([^:=,\\s]+)\\s*[:=]\\s*([^,\\s]+)").FindAllStringSubmatch(target[2], -1) {
-				option[2] = strings.ToLower(option[2])
-				switch strings.ToLower(option[1]) {
-				case "utc":
-					if option[2] == "1" || option[2] == "true" || option[2] == "on" || option[2] == "yes" {
-						this.optionUTC = true
-					}
-				case "level":
-					this.level = severities[strings.ToLower(option[2])]
+			case key == "rate" || strings.HasPrefix(key, "rate."):
+				r, _ := strconv.ParseFloat(option[2], 64)
+				if level, scoped, ok := scopedLevel(key, "rate"); !scoped {
+					defaultRate = r
+				} else if ok {
+					rateByLevel[level] = r
+				}
+			case key == "burst" || strings.HasPrefix(key, "burst."):
+				b, _ := strconv.ParseInt(option[2], 10, 64)
+				if level, scoped, ok := scopedLevel(key, "burst"); !scoped {
+					defaultBurst = b
+				} else if ok {
+					burstByLevel[level] = b
 				}
 			}
 		}
 	}
+	for _, level := range []syslog.Priority{syslog.LOG_ERR, syslog.LOG_WARNING, syslog.LOG_INFO, syslog.LOG_DEBUG} {
+		sampleN := defaultSample
+		if v, ok := sampleByLevel[level]; ok {
+			sampleN = v
+		}
+		rate := defaultRate
+		if v, ok := rateByLevel[level]; ok {
+			rate = v
+		}
+		burst := defaultBurst
+		if v, ok := burstByLevel[level]; ok {
+			burst = v
+		}
+		if sampleN <= 1 && rate <= 0 {
+			continue
+		}
+		if burst <= 0 {
+			burst = 1
+		}
+		if this.limiters == nil {
+			this.limiters = map[syslog.Priority]*levelLimiter{}
+		}
+		this.limiters[level] = &levelLimiter{sampleN: sampleN, rate: rate, burst: burst, tokens: burst}
+	}
+	for _, clause := range clauses {
+		switch strings.ToLower(clause[1]) {
+		case "file":
+			if sink := newFileSink(clause[2], defaultLevel); sink != nil {
+				this.sinks = append(this.sinks, sink)
+			}
+		case "console":
+			this.sinks = append(this.sinks, newConsoleSink(clause[2], defaultLevel))
+		case "syslog":
+			this.sinks = append(this.sinks, newSyslogSink(clause[2], name, defaultLevel))
+		}
+	}
+	this.recomputeLevel()
 	return this
 }
 
-func (this *ULog) Close() {
+// AddSink registers an additional Sink (built-in or user-supplied) that every
+// subsequent log call fans out to alongside whatever Load configured.
+func (this *ULog) AddSink(sink Sink) *ULog {
 	this.Lock()
 	defer this.Unlock()
-	if this.syslogHandle != nil {
-		this.syslogHandle.Close()
-		this.syslogHandle = nil
+	this.sinks = append(this.sinks, sink)
+	this.recomputeLevel()
+	return this
+}
+
+// recomputeLevel refreshes the fast top-level gate in log() to the most
+// verbose level across all registered sinks. Callers must hold this.Mutex.
+func (this *ULog) recomputeLevel() {
+	level := syslog.Priority(-1)
+	for _, sink := range this.sinks {
+		if sink.Level() > level {
+			level = sink.Level()
+		}
 	}
-	if this.fileHandle != nil {
-		this.fileHandle.Close()
-		this.fileHandle = nil
+	this.level = level
+}
+
+func (this *ULog) Close() {
+	this.Lock()
+	defer this.Unlock()
+	for _, sink := range this.sinks {
+		sink.Close()
 	}
 }
 
+// levelSetter is implemented by the built-in sinks so SetLevel can keep
+// adjusting every destination at once, matching this package's historical
+// single-knob behavior.
+type levelSetter interface {
+	setLevel(syslog.Priority)
+}
+
 func (this *ULog) SetLevel(level string) {
 	level = strings.ToLower(level)
+	var parsed syslog.Priority
 	switch level {
 	case "error":
-		this.level = syslog.LOG_ERR
+		parsed = syslog.LOG_ERR
 	case "warning":
-		this.level = syslog.LOG_WARNING
+		parsed = syslog.LOG_WARNING
 	case "info":
-		this.level = syslog.LOG_INFO
+		parsed = syslog.LOG_INFO
 	case "debug":
-		this.level = syslog.LOG_DEBUG
+		parsed = syslog.LOG_DEBUG
+	default:
+		return
+	}
+	this.Lock()
+	defer this.Unlock()
+	for _, sink := range this.sinks {
+		if setter, ok := sink.(levelSetter); ok {
+			setter.setLevel(parsed)
+		}
 	}
+	this.recomputeLevel()
+}
+
+// root returns the ULog holding the real sink/option configuration: this,
+// or the logger it ultimately delegates to if this is a With()-derived
+// child.
+func (this *ULog) root() *ULog {
+	node := this
+	for node.parent != nil {
+		node = node.parent
+	}
+	return node
+}
+
+// SetCallerMarshalFunc overrides how a captured call site is rendered into
+// the string stored on Record.Caller. Takes effect for this logger's root,
+// so With()-derived children pick it up too. Safe to call concurrently with
+// in-flight log calls: callerMarshal is an atomic.Value, read lock-free by
+// captureCaller.
+func (this *ULog) SetCallerMarshalFunc(fn CallerMarshalFunc) *ULog {
+	root := this.root()
+	root.callerMarshal.Store(fn)
+	root.callerCache.Range(func(key, _ interface{}) bool {
+		root.callerCache.Delete(key)
+		return true
+	})
+	return this
+}
+
+// captureCaller renders the call site skip frames above this call, or ""
+// if option(caller=...) wasn't set. Results are cached by program counter
+// so repeated calls from the same line (the common case: a log call inside
+// a loop) skip re-formatting. callerMode/callerMarshal are read without
+// holding root's lock, since Load()/SetCallerMarshalFunc() can run
+// concurrently with log calls on a hot path; both fields are accessed
+// atomically to keep that race-free.
+func (this *ULog) captureCaller(skip int) string {
+	root := this.root()
+	mode := int(atomic.LoadInt32(&root.callerMode))
+	if mode == callerOff {
+		return ""
+	}
+	pc, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return ""
+	}
+	if cached, ok := root.callerCache.Load(pc); ok {
+		return cached.(string)
+	}
+	var rendered string
+	if fn, ok := root.callerMarshal.Load().(CallerMarshalFunc); ok && fn != nil {
+		rendered = fn(pc, file, line)
+	} else {
+		rendered = defaultCallerMarshal(mode, file, line)
+	}
+	root.callerCache.Store(pc, rendered)
+	return rendered
+}
+
+// defaultCallerMarshal formats file:line per option(caller=...): short keeps
+// only the final path element, pkg keeps the enclosing directory too (the
+// common "package/file.go" shape), full keeps the path runtime.Caller gave.
+func defaultCallerMarshal(mode int, file string, line int) string {
+	switch mode {
+	case callerFull:
+		return fmt.Sprintf("%s:%d", file, line)
+	case callerPkg:
+		return fmt.Sprintf("%s:%d", filepath.Join(filepath.Base(filepath.Dir(file)), filepath.Base(file)), line)
+	default:
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+}
+
+func parseSize(value string) int64 {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1 << 30
+		value = strings.TrimSuffix(value, "G")
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1 << 20
+		value = strings.TrimSuffix(value, "M")
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1 << 10
+		value = strings.TrimSuffix(value, "K")
+	case strings.HasSuffix(value, "B"):
+		value = strings.TrimSuffix(value, "B")
+	}
+	size, _ := strconv.ParseInt(value, 10, 64)
+	return size * multiplier
 }
 
 func strftime(layout string, base time.Time) string {
@@ -360,130 +516,237 @@ func strftime(layout string, base time.Time) string {
 	return strings.Join(output, "")
 }
 
-func (this *ULog) log(severity syslog.Priority, xlayout interface{}, a ...interface{}) {
-	var err error
-	if this.level < severity || (!this.syslog && !this.file && !this.console) {
+// log builds a Record from xlayout/a and fans it out to every sink whose
+// Level() accepts severity. Map payloads (from Info()/.../Msg() or a raw
+// map argument) become Record.Fields; string payloads are formatted
+// printf-style into Record.Message, exactly as the old single-writer log()
+// did before sinks existed. caller is the already-rendered call site from
+// entry(), or "" when caller enrichment is off.
+func (this *ULog) log(severity syslog.Priority, caller string, xlayout interface{}, a ...interface{}) {
+	if this.parent != nil {
+		this.parent.log(severity, caller, this.mergeContext(xlayout), a...)
+		return
+	}
+	if xlayout == nil {
+		return
+	}
+	level, sinks, limiters, optionUTC := this.snapshot()
+	if severity > level || len(sinks) == 0 {
 		return
 	}
-	layout := ""
+	if len(limiters) > 0 {
+		this.reportSuppressed(sinks, limiters, optionUTC)
+		if limiter := limiters[severity]; limiter != nil && !limiter.allow() {
+			return
+		}
+	}
+	var rec Record
+	rec.Severity = severity
+	rec.Caller = caller
 	switch reflect.TypeOf(xlayout).Kind() {
 	case reflect.Map:
-		var buffer bytes.Buffer
-
-		encoder := json.NewEncoder(&buffer)
-		encoder.SetEscapeHTML(false)
-		if err := encoder.Encode(xlayout); err == nil {
-			layout = "%s"
-			a = []interface{}{bytes.TrimSpace(buffer.Bytes())}
-		}
-	case reflect.String:
-		layout = xlayout.(string)
-	}
-	layout = strings.TrimSpace(layout)
-	if this.syslog {
-		if this.syslogHandle == nil {
-			this.Lock()
-			if this.syslogHandle == nil {
-				protocol := ""
-				if this.syslogRemote != "" {
-					protocol = "udp"
+		if fields, ok := xlayout.(map[string]interface{}); ok {
+			rec.Fields = fields
+		} else {
+			var buffer bytes.Buffer
+			encoder := json.NewEncoder(&buffer)
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(xlayout); err == nil {
+				var decoded map[string]interface{}
+				if json.Unmarshal(buffer.Bytes(), &decoded) == nil {
+					rec.Fields = decoded
 				}
-				if this.syslogHandle, err = syslog.Dial(protocol, this.syslogRemote, this.syslogFacility, this.syslogName); err != nil {
-					this.syslogHandle = nil
-				}
-			}
-			this.Unlock()
-		}
-		if this.syslogHandle != nil {
-			switch severity {
-			case syslog.LOG_ERR:
-				this.syslogHandle.Err(fmt.Sprintf(layout, a...))
-			case syslog.LOG_WARNING:
-				this.syslogHandle.Warning(fmt.Sprintf(layout, a...))
-			case syslog.LOG_INFO:
-				this.syslogHandle.Info(fmt.Sprintf(layout, a...))
-			case syslog.LOG_DEBUG:
-				this.syslogHandle.Debug(fmt.Sprintf(layout, a...))
 			}
 		}
+	case reflect.String:
+		rec.Message = strings.TrimSpace(fmt.Sprintf(xlayout.(string), a...))
+	default:
+		return
 	}
+	this.dispatch(sinks, optionUTC, rec)
+}
+
+// snapshot returns the level gate, sink list, limiter map, and UTC flag
+// under this.Mutex, so log()/dispatch()/reportSuppressed() never read
+// fields that Load()/AddSink()/Close() are concurrently mutating.
+func (this *ULog) snapshot() (syslog.Priority, []Sink, map[syslog.Priority]*levelLimiter, bool) {
+	this.Lock()
+	defer this.Unlock()
+	return this.level, this.sinks, this.limiters, this.optionUTC
+}
+
+// dispatch stamps rec.Time and fans it out to every sink whose Level()
+// accepts it. Shared by log() and reportSuppressed(), which builds its own
+// synthetic Record rather than going through log()'s limiter gate. sinks and
+// optionUTC are whatever this.log() already took off this.snapshot(), so
+// dispatch itself never needs the lock.
+func (this *ULog) dispatch(sinks []Sink, optionUTC bool, rec Record) {
 	now := time.Now()
-	if this.optionUTC {
+	if optionUTC {
 		now = now.UTC()
 	} else {
 		now = now.Local()
 	}
-	if this.file {
-		if now.Sub(this.lastCheck) >= time.Second || this.fileHandle == nil {
-			this.lastCheck = now
-			this.Lock()
-			path := strftime(this.filePath, now)
-			if path != this.filePreviousPath {
-				if this.fileHandle != nil {
-					this.fileHandle.Close()
-					this.fileHandle = nil
-				}
-				this.filePreviousPath = path
-			}
-			if this.fileHandle == nil {
-				os.MkdirAll(filepath.Dir(path), 0755)
-				if this.fileHandle, err = os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
-					this.fileHandle = nil
-				}
-			}
-			this.Unlock()
+	rec.Time = now
+	for _, sink := range sinks {
+		if rec.Severity > sink.Level() {
+			continue
 		}
-		if this.fileHandle != nil {
-			prefix := ""
-			switch this.fileTime {
-			case TIME_DATE:
-				prefix = fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d ", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
-			case TIME_TIMESTAMP:
-				prefix = fmt.Sprintf("%d ", now.Unix())
-			}
-			if this.fileSeverity {
-				prefix += severityLabels[severity]
-			}
-			this.Lock()
-			this.fileHandle.WriteString(fmt.Sprintf(prefix+layout+"\n", a...))
-			this.Unlock()
+		sink.Write(rec)
+	}
+}
+
+// reportSuppressed emits one synthetic "N messages suppressed at LEVEL"
+// record per severity per second, for levels whose sample/rate limiter
+// dropped at least one message since the last report. The once-a-second
+// gate is a lock-free CAS so it costs nothing beyond the limiter check
+// itself on every other call.
+func (this *ULog) reportSuppressed(sinks []Sink, limiters map[syslog.Priority]*levelLimiter, optionUTC bool) {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&this.suppressCheck)
+	if now-last < int64(time.Second) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&this.suppressCheck, last, now) {
+		return
+	}
+	for severity, limiter := range limiters {
+		count := atomic.SwapUint64(&limiter.suppressed, 0)
+		if count == 0 {
+			continue
 		}
+		this.dispatch(sinks, optionUTC, Record{
+			Severity: severity,
+			Message:  fmt.Sprintf("%d messages suppressed at %s", count, strings.TrimSpace(severityLabels[severity])),
+		})
+	}
+}
+
+// entry dispatches to the printf/map API when called with arguments (old
+// style: Info("failed: %s", err)), and otherwise starts a structured Event
+// for chaining (new style: Info().Str("user", u).Msg("failed")). The call
+// site is captured here, one frame above Error/Warn/Info/Debug, so it's the
+// same for both styles.
+func (this *ULog) entry(severity syslog.Priority, a []interface{}) *Event {
+	caller := this.captureCaller(3)
+	if len(a) == 0 {
+		return &Event{log: this, severity: severity, fields: map[string]interface{}{}, caller: caller}
 	}
-	if this.console {
-		prefix := ""
-		switch this.consoleTime {
-		case TIME_DATE:
-			prefix = fmt.Sprintf("%04d-%02d-%02d %02d:%02d:%02d ", now.Year(), now.Month(), now.Day(), now.Hour(), now.Minute(), now.Second())
-		case TIME_TIMESTAMP:
-			prefix = fmt.Sprintf("%d ", now.Unix())
+	this.log(severity, caller, a[0], a[1:]...)
+	return nil
+}
+
+func (this *ULog) Error(a ...interface{}) *Event {
+	return this.entry(syslog.LOG_ERR, a)
+}
+func (this *ULog) Warn(a ...interface{}) *Event {
+	return this.entry(syslog.LOG_WARNING, a)
+}
+func (this *ULog) Info(a ...interface{}) *Event {
+	return this.entry(syslog.LOG_INFO, a)
+}
+func (this *ULog) Debug(a ...interface{}) *Event {
+	return this.entry(syslog.LOG_DEBUG, a)
+}
+
+// mergeContext folds this logger's inherited With() fields into an outgoing
+// record: merged into the map for structured/map payloads, or rendered as a
+// "key=value " prefix ahead of printf-style string layouts.
+func (this *ULog) mergeContext(xlayout interface{}) interface{} {
+	if len(this.context) == 0 || xlayout == nil {
+		return xlayout
+	}
+	switch reflect.TypeOf(xlayout).Kind() {
+	case reflect.Map:
+		fields, ok := xlayout.(map[string]interface{})
+		if !ok {
+			return xlayout
 		}
-		if this.consoleSeverity {
-			if this.consoleColors {
-				prefix += fmt.Sprintf("%s%s\x1b[0m", severityColors[severity], severityLabels[severity])
-			} else {
-				prefix += severityLabels[severity]
-			}
+		merged := make(map[string]interface{}, len(this.context)+len(fields))
+		for key, value := range this.context {
+			merged[key] = value
 		}
-		if reflect.TypeOf(xlayout).Kind() == reflect.Map && this.consoleColors {
-			for index, _ := range a {
-				a[index] = this.consoleColorizer.ReplaceAllString(fmt.Sprintf("%s", a[index]), "\"\x1b[37m$1\x1b[0m\":")
-			}
+		for key, value := range fields {
+			merged[key] = value
+		}
+		return merged
+	case reflect.String:
+		keys := make([]string, 0, len(this.context))
+		for key := range this.context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var prefix strings.Builder
+		for _, key := range keys {
+			fmt.Fprintf(&prefix, "%s=%v ", key, this.context[key])
 		}
-		this.Lock()
-		fmt.Fprintf(this.consoleHandle, prefix+layout+"\n", a...)
-		this.Unlock()
+		return strings.ReplaceAll(prefix.String(), "%", "%%") + xlayout.(string)
+	default:
+		return xlayout
 	}
 }
 
-func (this *ULog) Error(layout interface{}, a ...interface{}) {
-	this.log(syslog.LOG_ERR, layout, a...)
+// Event is a chainable structured log record, in the spirit of zerolog:
+// log.Info().Str("user", u).Int("code", 500).Msg("failed"). It is built on
+// top of the existing log() dispatcher, so it gets JSON encoding on
+// file/syslog sinks and colorized key/value pairs on the console for free.
+type Event struct {
+	log      *ULog
+	severity syslog.Priority
+	fields   map[string]interface{}
+	caller   string
 }
-func (this *ULog) Warn(layout interface{}, a ...interface{}) {
-	this.log(syslog.LOG_WARNING, layout, a...)
+
+func (this *Event) Str(key, value string) *Event {
+	if this == nil {
+		return this
+	}
+	this.fields[key] = value
+	return this
 }
-func (this *ULog) Info(layout interface{}, a ...interface{}) {
-	this.log(syslog.LOG_INFO, layout, a...)
+
+func (this *Event) Int(key string, value int) *Event {
+	if this == nil {
+		return this
+	}
+	this.fields[key] = value
+	return this
+}
+
+// Msg fires the event. Calling Msg on a nil Event (e.g. the receiver of an
+// old-style printf call, which already fired and returns nil) is a no-op.
+func (this *Event) Msg(message string) {
+	if this == nil {
+		return
+	}
+	if message != "" {
+		this.fields["message"] = message
+	}
+	this.log.log(this.severity, this.caller, this.fields)
+}
+
+// Context accumulates fields for a sub-logger built via ULog.With().
+type Context struct {
+	log    *ULog
+	fields map[string]interface{}
 }
-func (this *ULog) Debug(layout interface{}, a ...interface{}) {
-	this.log(syslog.LOG_DEBUG, layout, a...)
+
+func (this *ULog) With() *Context {
+	return &Context{log: this, fields: map[string]interface{}{}}
+}
+
+func (this *Context) Str(key, value string) *Context {
+	this.fields[key] = value
+	return this
+}
+
+func (this *Context) Int(key string, value int) *Context {
+	this.fields[key] = value
+	return this
+}
+
+// Logger returns a child *ULog that writes through the parent's sinks,
+// prepending this context's fields to every record it emits.
+func (this *Context) Logger() *ULog {
+	return &ULog{parent: this.log, context: this.fields}
 }